@@ -58,6 +58,11 @@ func initCircuitBreaker(watcher *ConfigWatcher) *circuitbreak.CBSuite {
 	}
 
 	watcher.AddCallback(onChangeCallback)
+
+	watcher.AddErrorCallback(func(err error) {
+		klog.Errorf("[local] %s client circuitbreaker config reload failed, keep current config: %v", watcher.Key(), err)
+	})
+
 	return cb
 }
 
@@ -68,5 +68,9 @@ func initRetryContainer(watcher *ConfigWatcher) *retry.Container {
 
 	watcher.AddCallback(onChangeCallback)
 
+	watcher.AddErrorCallback(func(err error) {
+		klog.Errorf("[local] %s client retry config reload failed, keep current policies: %v", watcher.Key(), err)
+	})
+
 	return retryContainer
 }
\ No newline at end of file
@@ -0,0 +1,293 @@
+// Copyright 2023 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configmapwatcher watches a Kubernetes ConfigMap and dispatches its
+// data to registered callbacks, mirroring filewatcher.FileWatcher so it can
+// be used as a monitor.Source for clusters that keep retry/circuitbreaker/
+// limiter config in a ConfigMap instead of a mounted file.
+package configmapwatcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// defaultResyncPeriod is how often the informer resyncs the ConfigMap from
+// its local cache, independent of the watch stream.
+const defaultResyncPeriod = 10 * time.Minute
+
+// ConfigMapWatcher is used for ConfigMap monitoring, analogous to
+// filewatcher.FileWatcher but backed by a Kubernetes informer.
+type ConfigMapWatcher interface {
+	RegisterCallback(callback func(data []byte), key string) error
+	DeregisterCallback(key string)
+	RegisterErrorCallback(callback func(err error), key string) error
+	DeregisterErrorCallback(key string)
+	StartWatching() error
+	StopWatching()
+	CallOnceAll() error
+	CallOnceSpecific(key string) error
+}
+
+// configMapWatcher watches a single named ConfigMap via a SharedInformer.
+type configMapWatcher struct {
+	client         kubernetes.Interface
+	namespace      string
+	name           string
+	dataKey        string                       // the key within ConfigMap.Data carrying the config bytes
+	callbacks      map[string]func(data []byte) // Custom functions to be executed when the ConfigMap changes.
+	errorCallbacks map[string]func(err error)    // Custom functions to be executed when watching/fetching the ConfigMap fails.
+	informer       cache.SharedIndexInformer
+	done           chan struct{} // A channel for signaling the watcher to stop.
+	mu             sync.Mutex
+}
+
+// NewConfigMapWatcher creates a new ConfigMapWatcher instance that watches
+// the ConfigMap `name` in `namespace`, dispatching the bytes stored under
+// `dataKey` whenever it is created or updated.
+func NewConfigMapWatcher(client kubernetes.Interface, namespace, name, dataKey string) (ConfigMapWatcher, error) {
+	if client == nil {
+		return nil, errors.New("kubernetes client is nil")
+	}
+	if namespace == "" || name == "" {
+		return nil, errors.New("configmap namespace and name must not be empty")
+	}
+	if dataKey == "" {
+		return nil, errors.New("configmap data key must not be empty")
+	}
+
+	return &configMapWatcher{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		dataKey:   dataKey,
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// RegisterCallback sets the callback function.
+func (cw *configMapWatcher) RegisterCallback(callback func(data []byte), key string) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if cw.callbacks == nil {
+		cw.callbacks = make(map[string]func(data []byte))
+	}
+
+	if _, exists := cw.callbacks[key]; exists {
+		return errors.New("key " + key + "already exists")
+	}
+
+	cw.callbacks[key] = callback
+	return nil
+}
+
+// DeregisterCallback remove callback function.
+func (cw *configMapWatcher) DeregisterCallback(key string) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if _, exists := cw.callbacks[key]; !exists {
+		klog.Warnf("[configmap] ConfigMapWatcher callback %s not registered", key)
+		return
+	}
+	delete(cw.callbacks, key)
+	klog.Infof("[configmap] configmap %s/%s deregistered callback: %v\n", cw.namespace, cw.name, key)
+}
+
+// RegisterErrorCallback sets the error callback function, invoked whenever
+// watching or fetching the ConfigMap fails.
+func (cw *configMapWatcher) RegisterErrorCallback(callback func(err error), key string) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if cw.errorCallbacks == nil {
+		cw.errorCallbacks = make(map[string]func(err error))
+	}
+
+	if _, exists := cw.errorCallbacks[key]; exists {
+		return errors.New("key " + key + "already exists")
+	}
+
+	cw.errorCallbacks[key] = callback
+	return nil
+}
+
+// DeregisterErrorCallback remove error callback function.
+func (cw *configMapWatcher) DeregisterErrorCallback(key string) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if _, exists := cw.errorCallbacks[key]; !exists {
+		klog.Warnf("[configmap] ConfigMapWatcher error callback %s not registered", key)
+		return
+	}
+	delete(cw.errorCallbacks, key)
+	klog.Infof("[configmap] configmap %s/%s deregistered error callback: %v\n", cw.namespace, cw.name, key)
+}
+
+// notifyError invokes every registered error callback.
+func (cw *configMapWatcher) notifyError(err error) {
+	cw.mu.Lock()
+	callbacks := make([]func(error), 0, len(cw.errorCallbacks))
+	for _, v := range cw.errorCallbacks {
+		callbacks = append(callbacks, v)
+	}
+	cw.mu.Unlock()
+
+	for _, v := range callbacks {
+		v(err)
+	}
+}
+
+// StartWatching starts a SharedInformer filtered down to this ConfigMap and
+// blocks until the initial cache sync completes.
+func (cw *configMapWatcher) StartWatching() error {
+	factory := informers.NewSharedInformerFactoryWithOptions(cw.client, defaultResyncPeriod,
+		informers.WithNamespace(cw.namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", cw.name).String()
+		}),
+	)
+
+	cw.informer = factory.Core().V1().ConfigMaps().Informer()
+	_, err := cw.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			cw.onConfigMapChanged(obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			cw.onConfigMapChanged(newObj)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				klog.Errorf("configmap watcher panic: %v\n", r)
+			}
+		}()
+		factory.Start(cw.done)
+	}()
+
+	if !cache.WaitForCacheSync(cw.done, cw.informer.HasSynced) {
+		return errors.New("configmap watcher: stopped before cache sync completed")
+	}
+	return nil
+}
+
+// StopWatching stops monitoring the ConfigMap.
+func (cw *configMapWatcher) StopWatching() {
+	klog.Infof("[configmap] stop watching configmap: %s/%s", cw.namespace, cw.name)
+	close(cw.done)
+}
+
+// CallOnceAll calls the callback function list once.
+func (cw *configMapWatcher) CallOnceAll() error {
+	data, err := cw.fetchData()
+	if err != nil {
+		cw.notifyError(err)
+		return err
+	}
+
+	cw.mu.Lock()
+	callbacks := make([]func(data []byte), 0, len(cw.callbacks))
+	for _, v := range cw.callbacks {
+		callbacks = append(callbacks, v)
+	}
+	cw.mu.Unlock()
+
+	for _, v := range callbacks {
+		v(data)
+	}
+	return nil
+}
+
+// CallOnceSpecific calls the callback function once by key.
+func (cw *configMapWatcher) CallOnceSpecific(key string) error {
+	data, err := cw.fetchData()
+	if err != nil {
+		cw.notifyError(err)
+		return err
+	}
+
+	cw.mu.Lock()
+	callback, ok := cw.callbacks[key]
+	cw.mu.Unlock()
+
+	if !ok {
+		return errors.New("not found callback for key: " + key)
+	}
+	callback(data)
+	return nil
+}
+
+// onConfigMapChanged dispatches the data key of an added/updated ConfigMap to
+// every registered callback, exactly as fileWatcher.CallOnceAll does.
+func (cw *configMapWatcher) onConfigMapChanged(obj interface{}) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+
+	data, ok := cm.Data[cw.dataKey]
+	if !ok {
+		err := errors.New("configmap [" + cw.namespace + "/" + cw.name + "] has no data key: " + cw.dataKey)
+		klog.Warnf("[configmap] %s/%s has no data key %q, skip", cw.namespace, cw.name, cw.dataKey)
+		cw.notifyError(err)
+		return
+	}
+
+	cw.mu.Lock()
+	callbacks := make([]func(data []byte), 0, len(cw.callbacks))
+	for _, v := range cw.callbacks {
+		callbacks = append(callbacks, v)
+	}
+	cw.mu.Unlock()
+
+	for _, v := range callbacks {
+		v([]byte(data))
+	}
+}
+
+// fetchData reads the ConfigMap directly from the API server, used by
+// CallOnceAll/CallOnceSpecific for an on-demand refresh.
+func (cw *configMapWatcher) fetchData() ([]byte, error) {
+	cm, err := cw.client.CoreV1().ConfigMaps(cw.namespace).Get(context.Background(), cw.name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.New("configmap [" + cw.namespace + "/" + cw.name + "] not exist")
+		}
+		return nil, err
+	}
+
+	data, ok := cm.Data[cw.dataKey]
+	if !ok {
+		return nil, errors.New("configmap [" + cw.namespace + "/" + cw.name + "] has no data key: " + cw.dataKey)
+	}
+	return []byte(data), nil
+}
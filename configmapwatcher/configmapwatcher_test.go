@@ -0,0 +1,176 @@
+// Copyright 2023 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmapwatcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// waitFor polls cond until it returns true or timeout elapses, failing the
+// test otherwise. Informer dispatch runs on its own goroutine, so tests
+// can't assert immediately after mutating the fake clientset.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("condition not met within %s", timeout)
+}
+
+// TestConfigMapWatcher_AddUpdateDispatch covers the informer-driven path:
+// a ConfigMap that already exists when StartWatching is called fires an
+// add event, and a later update fires again with the new data.
+func TestConfigMapWatcher_AddUpdateDispatch(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data:       map[string]string{"config.yaml": "v1"},
+	}
+	client := fake.NewSimpleClientset(cm)
+
+	cw, err := NewConfigMapWatcher(client, "default", "app-config", "config.yaml")
+	if err != nil {
+		t.Fatalf("NewConfigMapWatcher: %v", err)
+	}
+	defer cw.StopWatching()
+
+	var mu sync.Mutex
+	var got string
+	if err := cw.RegisterCallback(func(data []byte) {
+		mu.Lock()
+		got = string(data)
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterCallback: %v", err)
+	}
+
+	if err := cw.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return got == "v1"
+	})
+
+	cm2 := cm.DeepCopy()
+	cm2.Data["config.yaml"] = "v2"
+	if _, err := client.CoreV1().ConfigMaps("default").Update(context.Background(), cm2, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update configmap: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return got == "v2"
+	})
+}
+
+// TestConfigMapWatcher_MissingDataKey covers the error path: a ConfigMap
+// that doesn't carry the configured data key notifies error callbacks
+// instead of a regular callback.
+func TestConfigMapWatcher_MissingDataKey(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data:       map[string]string{"other-key": "v1"},
+	}
+	client := fake.NewSimpleClientset(cm)
+
+	cw, err := NewConfigMapWatcher(client, "default", "app-config", "config.yaml")
+	if err != nil {
+		t.Fatalf("NewConfigMapWatcher: %v", err)
+	}
+	defer cw.StopWatching()
+
+	var mu sync.Mutex
+	var sawCallback bool
+	var gotErr error
+	if err := cw.RegisterCallback(func(data []byte) {
+		mu.Lock()
+		sawCallback = true
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterCallback: %v", err)
+	}
+	if err := cw.RegisterErrorCallback(func(err error) {
+		mu.Lock()
+		gotErr = err
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterErrorCallback: %v", err)
+	}
+
+	if err := cw.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return gotErr != nil
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if sawCallback {
+		t.Fatalf("expected the regular callback not to fire for a configmap missing the data key")
+	}
+}
+
+// TestConfigMapWatcher_CallOnceAll_MissingDataKey covers the same missing-key
+// path through the on-demand fetchData route used by CallOnceAll.
+func TestConfigMapWatcher_CallOnceAll_MissingDataKey(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data:       map[string]string{"other-key": "v1"},
+	}
+	client := fake.NewSimpleClientset(cm)
+
+	cw, err := NewConfigMapWatcher(client, "default", "app-config", "config.yaml")
+	if err != nil {
+		t.Fatalf("NewConfigMapWatcher: %v", err)
+	}
+
+	var mu sync.Mutex
+	var gotErr error
+	if err := cw.RegisterErrorCallback(func(err error) {
+		mu.Lock()
+		gotErr = err
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterErrorCallback: %v", err)
+	}
+
+	if err := cw.CallOnceAll(); err == nil {
+		t.Fatalf("expected CallOnceAll to return an error for a configmap missing the data key")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotErr == nil {
+		t.Fatalf("expected the error callback to fire for a configmap missing the data key")
+	}
+}
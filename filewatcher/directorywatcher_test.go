@@ -0,0 +1,157 @@
+// Copyright 2023 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filewatcher
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestDirectoryWatcher_IncludeExcludeAndMerge covers the glob filtering and
+// merge-on-change behavior of NewDirectoryWatcher: matching fragments are
+// combined into one document and non-matching files are ignored.
+func TestDirectoryWatcher_IncludeExcludeAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "retry.yaml"), []byte("retry:\n  MethodA:\n    x: 1\n"), 0o644); err != nil {
+		t.Fatalf("seed retry.yaml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "limiter.yaml"), []byte("limiter:\n  qps: 10\n"), 0o644); err != nil {
+		t.Fatalf("seed limiter.yaml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignore me"), 0o644); err != nil {
+		t.Fatalf("seed notes.txt: %v", err)
+	}
+
+	dw, err := NewDirectoryWatcher(dir, WithInclude("*.yaml"), WithDebounce(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewDirectoryWatcher: %v", err)
+	}
+	defer dw.StopWatching()
+
+	var mu sync.Mutex
+	var got []byte
+	if err := dw.RegisterCallback(func(data []byte) {
+		mu.Lock()
+		got = data
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterCallback: %v", err)
+	}
+
+	if err := dw.CallOnceAll(); err != nil {
+		t.Fatalf("CallOnceAll: %v", err)
+	}
+
+	mu.Lock()
+	merged := got
+	mu.Unlock()
+
+	var initial map[string]interface{}
+	if err := yaml.Unmarshal(merged, &initial); err != nil {
+		t.Fatalf("merged output not valid YAML: %v", err)
+	}
+	if _, ok := initial["retry"]; !ok {
+		t.Fatalf("expected retry.yaml to be included, got %#v", initial)
+	}
+	if _, ok := initial["limiter"]; !ok {
+		t.Fatalf("expected limiter.yaml to be included, got %#v", initial)
+	}
+	if _, ok := initial["notes"]; ok {
+		t.Fatalf("expected notes.txt to be excluded by WithInclude, got %#v", initial)
+	}
+
+	if err := dw.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("still ignored"), 0o644); err != nil {
+		t.Fatalf("rewrite notes.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "limiter.yaml"), []byte("limiter:\n  qps: 20\n"), 0o644); err != nil {
+		t.Fatalf("rewrite limiter.yaml: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool {
+		mu.Lock()
+		data := got
+		mu.Unlock()
+
+		var cur map[string]interface{}
+		if yaml.Unmarshal(data, &cur) != nil {
+			return false
+		}
+		limiter, ok := cur["limiter"].(map[string]interface{})
+		return ok && limiter["qps"] == 20
+	})
+}
+
+// TestDirectoryWatcher_RecursiveNewSubdirectory covers recursive mode picking
+// up a sub-directory created after StartWatching, not just ones that existed
+// at construction time.
+func TestDirectoryWatcher_RecursiveNewSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "retry.yaml"), []byte("retry:\n  MethodA:\n    x: 1\n"), 0o644); err != nil {
+		t.Fatalf("seed retry.yaml: %v", err)
+	}
+
+	dw, err := NewDirectoryWatcher(dir, WithInclude("*.yaml"), WithRecursive(true), WithDebounce(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewDirectoryWatcher: %v", err)
+	}
+	defer dw.StopWatching()
+
+	var mu sync.Mutex
+	var got []byte
+	if err := dw.RegisterCallback(func(data []byte) {
+		mu.Lock()
+		got = data
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterCallback: %v", err)
+	}
+
+	if err := dw.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+
+	sub := filepath.Join(dir, "nested")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatalf("mkdir nested: %v", err)
+	}
+	// give the watcher a moment to register the new sub-directory before a
+	// file appears inside it, mirroring a deploy that mkdirs then writes.
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(sub, "limiter.yaml"), []byte("limiter:\n  qps: 30\n"), 0o644); err != nil {
+		t.Fatalf("write nested/limiter.yaml: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool {
+		mu.Lock()
+		data := got
+		mu.Unlock()
+
+		var cur map[string]interface{}
+		if yaml.Unmarshal(data, &cur) != nil {
+			return false
+		}
+		limiter, ok := cur["limiter"].(map[string]interface{})
+		return ok && limiter["qps"] == 30
+	})
+}
@@ -16,8 +16,12 @@ package filewatcher
 
 import (
 	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/cloudwego/kitex/pkg/klog"
 	"github.com/fsnotify/fsnotify"
@@ -28,23 +32,94 @@ type FileWatcher interface {
 	FilePath() string
 	RegisterCallback(callback func(data []byte), key string) error
 	DeregisterCallback(key string)
+	RegisterErrorCallback(callback func(err error), key string) error
+	DeregisterErrorCallback(key string)
 	StartWatching() error
 	StopWatching()
 	CallOnceAll() error
 	CallOnceSpecific(key string) error
 }
 
+// defaultRetryInterval and defaultRetryMaxInterval bound the backoff used by
+// NewFileWatcher while waiting for an atomically-renamed file to reappear.
+const (
+	defaultRetryInterval    = 500 * time.Millisecond
+	defaultRetryMaxInterval = 30 * time.Second
+)
+
+// atomicWriterDataDir is the indirection symlink kubelet's projected-volume
+// atomic_writer swaps to publish a new ConfigMap revision: the watched file
+// (e.g. app.yaml) is itself a symlink into "..data/", so an update touches
+// "..data" in the parent directory rather than app.yaml's own directory entry.
+const atomicWriterDataDir = "..data"
+
+// options holds the tunables shared by NewFileWatcher and NewDirectoryWatcher.
+type options struct {
+	include          []string      // glob patterns matched against a file's basename; nil/empty matches everything.
+	exclude          []string      // glob patterns matched against a file's basename; matches are skipped.
+	recursive        bool          // whether NewDirectoryWatcher also watches sub-directories.
+	debounce         time.Duration // events within this window are coalesced into a single reload.
+	retryInterval    time.Duration // initial backoff while waiting for a renamed/removed file to reappear.
+	retryMaxInterval time.Duration // cap on the backoff above.
+}
+
+// Option configures a FileWatcher created by NewFileWatcher or NewDirectoryWatcher.
+type Option func(*options)
+
+// WithInclude restricts NewDirectoryWatcher to files whose basename matches
+// one of the given glob patterns (see path/filepath.Match for the syntax).
+func WithInclude(patterns ...string) Option {
+	return func(o *options) { o.include = append(o.include, patterns...) }
+}
+
+// WithExclude skips files whose basename matches one of the given glob
+// patterns, even if they also match WithInclude.
+func WithExclude(patterns ...string) Option {
+	return func(o *options) { o.exclude = append(o.exclude, patterns...) }
+}
+
+// WithRecursive makes NewDirectoryWatcher also watch sub-directories.
+func WithRecursive(recursive bool) Option {
+	return func(o *options) { o.recursive = recursive }
+}
+
+// WithDebounce coalesces bursts of fs events within the given window into a
+// single reload, instead of re-reading the config once per event.
+func WithDebounce(d time.Duration) Option {
+	return func(o *options) { o.debounce = d }
+}
+
+// WithRetryInterval sets the initial backoff NewFileWatcher waits between
+// checks for a renamed/removed file to reappear, doubling up to
+// WithRetryMaxInterval. Defaults to defaultRetryInterval.
+func WithRetryInterval(d time.Duration) Option {
+	return func(o *options) { o.retryInterval = d }
+}
+
+// WithRetryMaxInterval caps the backoff set by WithRetryInterval. Defaults to
+// defaultRetryMaxInterval.
+func WithRetryMaxInterval(d time.Duration) Option {
+	return func(o *options) { o.retryMaxInterval = d }
+}
+
 // FileWatcher is used for file monitoring
 type fileWatcher struct {
-	filePath  string                       // The path to the file to be monitored.
-	callbacks map[string]func(data []byte) // Custom functions to be executed when the file changes.
-	watcher   *fsnotify.Watcher            // fsnotify file change watcher.
-	done      chan struct{}                // A channel for signaling the watcher to stop.
-	mu        sync.Mutex
+	filePath         string                       // The path to the file to be monitored.
+	parentDir        string                       // Directory of filePath; watched instead of filePath so rename/remove/recreate is observed.
+	baseName         string                       // Basename of filePath; events on the parent directory are filtered down to this.
+	debounce         time.Duration                // Coalesce window for bursts of matching events.
+	retryInterval    time.Duration                // Initial backoff while waiting for the file to reappear after Remove/Rename.
+	retryMaxInterval time.Duration                // Cap on the backoff above.
+	waiting          bool                         // Whether a waitForFileAndReload goroutine is already in flight, to single-flight it.
+	callbacks        map[string]func(data []byte) // Custom functions to be executed when the file changes.
+	errorCallbacks   map[string]func(err error)   // Custom functions to be executed when watching/reading/parsing the file fails.
+	watcher          *fsnotify.Watcher            // fsnotify file change watcher.
+	done             chan struct{}                // A channel for signaling the watcher to stop.
+	mu               sync.Mutex
 }
 
 // NewFileWatcher creates a new FileWatcher instance.
-func NewFileWatcher(filePath string) (FileWatcher, error) {
+func NewFileWatcher(filePath string, opts ...Option) (FileWatcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
@@ -58,10 +133,23 @@ func NewFileWatcher(filePath string) (FileWatcher, error) {
 		return nil, errors.New("file [" + filePath + "] not exist")
 	}
 
+	o := &options{
+		retryInterval:    defaultRetryInterval,
+		retryMaxInterval: defaultRetryMaxInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	fw := &fileWatcher{
-		filePath: filePath,
-		watcher:  watcher,
-		done:     make(chan struct{}),
+		filePath:         filePath,
+		parentDir:        filepath.Dir(filePath),
+		baseName:         filepath.Base(filePath),
+		debounce:         o.debounce,
+		retryInterval:    o.retryInterval,
+		retryMaxInterval: o.retryMaxInterval,
+		watcher:          watcher,
+		done:             make(chan struct{}),
 	}
 
 	return fw, nil
@@ -100,9 +188,59 @@ func (fw *fileWatcher) DeregisterCallback(key string) {
 	klog.Infof("[local] filewatcher to %v deregistered callback: %v\n", fw.filePath, key)
 }
 
-// Start starts monitoring file changes.
+// RegisterErrorCallback sets the error callback function, invoked whenever
+// watching, reading or parsing the file fails instead of the failure being
+// swallowed by a log line.
+func (fw *fileWatcher) RegisterErrorCallback(callback func(err error), key string) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.errorCallbacks == nil {
+		fw.errorCallbacks = make(map[string]func(err error))
+	}
+
+	if _, exists := fw.errorCallbacks[key]; exists {
+		return errors.New("key " + key + "already exists")
+	}
+
+	fw.errorCallbacks[key] = callback
+	return nil
+}
+
+// DeregisterErrorCallback remove error callback function.
+func (fw *fileWatcher) DeregisterErrorCallback(key string) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if _, exists := fw.errorCallbacks[key]; !exists {
+		klog.Warnf("[local] FileWatcher error callback %s not registered", key)
+		return
+	}
+	delete(fw.errorCallbacks, key)
+	klog.Infof("[local] filewatcher to %v deregistered error callback: %v\n", fw.filePath, key)
+}
+
+// notifyError invokes every registered error callback.
+func (fw *fileWatcher) notifyError(err error) {
+	fw.mu.Lock()
+	callbacks := make([]func(error), 0, len(fw.errorCallbacks))
+	for _, v := range fw.errorCallbacks {
+		callbacks = append(callbacks, v)
+	}
+	fw.mu.Unlock()
+
+	for _, v := range callbacks {
+		v(err)
+	}
+}
+
+// Start starts monitoring file changes. The parent directory, not the file
+// itself, is registered with fsnotify: most editors and config-management
+// tools (vim, kubectl apply on a projected ConfigMap, kubelet's
+// atomic_writer) replace a file by renaming a new one over it or swapping a
+// symlink, and a watch on the old inode never sees that happen.
 func (fw *fileWatcher) StartWatching() error {
-	err := fw.watcher.Add(fw.filePath)
+	err := fw.watcher.Add(fw.parentDir)
 	if err != nil {
 		return err
 	}
@@ -125,31 +263,123 @@ func (fw *fileWatcher) StopWatching() {
 	close(fw.done)
 }
 
-// StartWatching starts monitoring file changes.
+// start runs the event loop: events on the parent directory are filtered
+// down to filePath's basename, bursts within the debounce window are
+// coalesced into a single reload (Chmod included), and Remove/Rename (the
+// atomic-swap case) trigger a poll for the file to reappear instead of
+// stopping.
 func (fw *fileWatcher) start() {
 	defer fw.watcher.Close()
+
+	var debounceTimer *time.Timer
+	reload := func() {
+		if err := fw.CallOnceAll(); err != nil {
+			klog.Errorf("[local] read config file failed: %v\n", err)
+		}
+	}
+	scheduleReload := func() {
+		if fw.debounce <= 0 {
+			reload()
+			return
+		}
+		if debounceTimer == nil {
+			debounceTimer = time.AfterFunc(fw.debounce, reload)
+		} else {
+			debounceTimer.Reset(fw.debounce)
+		}
+	}
+
 	for {
 		select {
 		case event, ok := <-fw.watcher.Events:
 			if !ok {
 				return
 			}
-			if event.Has(fsnotify.Write) {
-				if err := fw.CallOnceAll(); err != nil {
-					klog.Errorf("[local] read config file failed: %v\n", err)
-				}
+			base := filepath.Base(event.Name)
+			if base != fw.baseName && base != atomicWriterDataDir {
+				continue
 			}
-			if event.Has(fsnotify.Remove) {
-				klog.Warnf("[local] file %s is removed, stop watching", fw.filePath)
-				fw.StopWatching()
+			switch {
+			case event.Has(fsnotify.Write), event.Has(fsnotify.Create), event.Has(fsnotify.Chmod):
+				// A chmod alone never changes content, but it's cheap to
+				// fold into the same debounced reload as write/create
+				// rather than give it its own case.
+				scheduleReload()
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				klog.Warnf("[local] file %s changed (%s), waiting for it to reappear", fw.filePath, event.Op)
+				fw.notifyError(errors.New("file [" + fw.filePath + "] is unavailable: " + event.Op.String()))
+				fw.startWaitForFileAndReload()
 			}
 		case err, ok := <-fw.watcher.Errors:
 			if !ok {
 				return
 			}
 			klog.Errorf("file watcher meet error: %v\n", err)
+			fw.notifyError(err)
+		case <-fw.done:
+			if debounceTimer != nil {
+				debounceTimer.Stop()
+			}
+			return
+		}
+	}
+}
+
+// startWaitForFileAndReload launches waitForFileAndReload unless one is
+// already in flight, so a Remove immediately followed by a Rename (or a
+// burst of either) doesn't stack up redundant polling goroutines that could
+// all fire CallOnceAll concurrently.
+func (fw *fileWatcher) startWaitForFileAndReload() {
+	fw.mu.Lock()
+	if fw.waiting {
+		fw.mu.Unlock()
+		return
+	}
+	fw.waiting = true
+	fw.mu.Unlock()
+
+	go fw.waitForFileAndReload()
+}
+
+// waitForFileAndReload polls, with exponential backoff between
+// retryInterval and retryMaxInterval, for filePath to reappear after a
+// Remove/Rename event, then reloads once it does. This covers the
+// atomic-rename and symlink-swap patterns used by editors and kubelet's
+// projected-volume atomic_writer, where the old watch target disappears for
+// a brief moment before the replacement lands under the same name.
+func (fw *fileWatcher) waitForFileAndReload() {
+	defer func() {
+		fw.mu.Lock()
+		fw.waiting = false
+		fw.mu.Unlock()
+	}()
+
+	backoff := fw.retryInterval
+	if backoff <= 0 {
+		backoff = defaultRetryInterval
+	}
+	maxBackoff := fw.retryMaxInterval
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxInterval
+	}
+
+	for {
+		select {
 		case <-fw.done:
 			return
+		case <-time.After(backoff):
+		}
+
+		if exist, err := utils.PathExists(fw.filePath); err == nil && exist {
+			if err := fw.CallOnceAll(); err != nil {
+				klog.Errorf("[local] read config file failed: %v\n", err)
+			}
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
 		}
 	}
 }
@@ -158,10 +388,18 @@ func (fw *fileWatcher) start() {
 func (fw *fileWatcher) CallOnceAll() error {
 	data, err := os.ReadFile(fw.filePath)
 	if err != nil {
+		fw.notifyError(err)
 		return err
 	}
 
+	fw.mu.Lock()
+	callbacks := make([]func(data []byte), 0, len(fw.callbacks))
 	for _, v := range fw.callbacks {
+		callbacks = append(callbacks, v)
+	}
+	fw.mu.Unlock()
+
+	for _, v := range callbacks {
 		v(data)
 	}
 	return nil
@@ -171,13 +409,341 @@ func (fw *fileWatcher) CallOnceAll() error {
 func (fw *fileWatcher) CallOnceSpecific(key string) error {
 	data, err := os.ReadFile(fw.filePath)
 	if err != nil {
+		fw.notifyError(err)
+		return err
+	}
+
+	fw.mu.Lock()
+	callback, ok := fw.callbacks[key]
+	fw.mu.Unlock()
+
+	if !ok {
+		return errors.New("not found callback for key: " + key)
+	}
+	callback(data)
+	return nil
+}
+
+// directoryWatcher watches every config fragment under a directory and, on
+// any change, merges all matching files into a single payload.
+type directoryWatcher struct {
+	dir            string                       // The directory to be monitored.
+	include        []string                     // Glob patterns a file's basename must match to be watched; empty matches everything.
+	exclude        []string                     // Glob patterns a file's basename must not match.
+	recursive      bool                         // Whether sub-directories are also watched.
+	debounce       time.Duration                // Coalesce window for bursts of fs events.
+	callbacks      map[string]func(data []byte) // Custom functions to be executed when the directory changes.
+	errorCallbacks map[string]func(err error)   // Custom functions to be executed when watching/reading the directory fails.
+	watcher        *fsnotify.Watcher            // fsnotify file change watcher.
+	done           chan struct{}                // A channel for signaling the watcher to stop.
+	mu             sync.Mutex
+}
+
+// NewDirectoryWatcher creates a FileWatcher that watches every file under dir
+// matching the WithInclude/WithExclude glob patterns, optionally descending
+// into sub-directories via WithRecursive. On any matching create/write/
+// rename/remove event it re-reads all matching files in stable lexicographic
+// order, deep-merges them into a single YAML document via mergeFragments
+// (last-writer-wins per key, lists replaced not appended), and feeds the
+// merged bytes to the registered callbacks.
+func NewDirectoryWatcher(dir string, opts ...Option) (FileWatcher, error) {
+	exist, err := utils.PathExists(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, errors.New("directory [" + dir + "] not exist")
+	}
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	dw := &directoryWatcher{
+		dir:       dir,
+		include:   o.include,
+		exclude:   o.exclude,
+		recursive: o.recursive,
+		debounce:  o.debounce,
+		watcher:   watcher,
+		done:      make(chan struct{}),
+	}
+
+	if err := dw.addWatches(); err != nil {
+		return nil, err
+	}
+
+	return dw, nil
+}
+
+// addWatches registers dir, and every sub-directory when recursive is set, with fsnotify.
+func (dw *directoryWatcher) addWatches() error {
+	return filepath.WalkDir(dw.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dw.dir && !dw.recursive {
+			return filepath.SkipDir
+		}
+		return dw.watcher.Add(path)
+	})
+}
+
+// matches reports whether path satisfies the include/exclude glob patterns.
+func (dw *directoryWatcher) matches(path string) bool {
+	base := filepath.Base(path)
+
+	if len(dw.include) > 0 {
+		included := false
+		for _, pattern := range dw.include {
+			if ok, _ := filepath.Match(pattern, base); ok {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, pattern := range dw.exclude {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// FilePath returns the directory address that the current object is listening to
+func (dw *directoryWatcher) FilePath() string { return dw.dir }
+
+// RegisterCallback sets the callback function.
+func (dw *directoryWatcher) RegisterCallback(callback func(data []byte), key string) error {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+
+	if dw.callbacks == nil {
+		dw.callbacks = make(map[string]func(data []byte))
+	}
+
+	if _, exists := dw.callbacks[key]; exists {
+		return errors.New("key " + key + "already exists")
+	}
+
+	dw.callbacks[key] = callback
+	return nil
+}
+
+// DeregisterCallback remove callback function.
+func (dw *directoryWatcher) DeregisterCallback(key string) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+
+	if _, exists := dw.callbacks[key]; !exists {
+		klog.Warnf("[local] DirectoryWatcher callback %s not registered", key)
+		return
+	}
+	delete(dw.callbacks, key)
+	klog.Infof("[local] directorywatcher to %v deregistered callback: %v\n", dw.dir, key)
+}
+
+// RegisterErrorCallback sets the error callback function.
+func (dw *directoryWatcher) RegisterErrorCallback(callback func(err error), key string) error {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+
+	if dw.errorCallbacks == nil {
+		dw.errorCallbacks = make(map[string]func(err error))
+	}
+
+	if _, exists := dw.errorCallbacks[key]; exists {
+		return errors.New("key " + key + "already exists")
+	}
+
+	dw.errorCallbacks[key] = callback
+	return nil
+}
+
+// DeregisterErrorCallback remove error callback function.
+func (dw *directoryWatcher) DeregisterErrorCallback(key string) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+
+	if _, exists := dw.errorCallbacks[key]; !exists {
+		klog.Warnf("[local] DirectoryWatcher error callback %s not registered", key)
+		return
+	}
+	delete(dw.errorCallbacks, key)
+	klog.Infof("[local] directorywatcher to %v deregistered error callback: %v\n", dw.dir, key)
+}
+
+// notifyError invokes every registered error callback.
+func (dw *directoryWatcher) notifyError(err error) {
+	dw.mu.Lock()
+	callbacks := make([]func(error), 0, len(dw.errorCallbacks))
+	for _, v := range dw.errorCallbacks {
+		callbacks = append(callbacks, v)
+	}
+	dw.mu.Unlock()
+
+	for _, v := range callbacks {
+		v(err)
+	}
+}
+
+// StartWatching starts monitoring the directory.
+func (dw *directoryWatcher) StartWatching() error {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				klog.Errorf("directory watcher panic: %v\n", r)
+			}
+		}()
+		dw.start()
+	}()
+
+	return nil
+}
+
+// StopWatching stops monitoring the directory.
+func (dw *directoryWatcher) StopWatching() {
+	klog.Infof("[local] stop watching directory: %s", dw.dir)
+	close(dw.done)
+}
+
+// start runs the event loop, coalescing bursts of matching events within the
+// debounce window before reloading.
+func (dw *directoryWatcher) start() {
+	defer dw.watcher.Close()
+
+	var debounceTimer *time.Timer
+	reload := func() {
+		if err := dw.CallOnceAll(); err != nil {
+			klog.Errorf("[local] read config directory failed: %v\n", err)
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-dw.watcher.Events:
+			if !ok {
+				return
+			}
+			if dw.recursive && event.Op&fsnotify.Create != 0 {
+				if info, statErr := os.Stat(event.Name); statErr == nil && info.IsDir() {
+					if err := dw.watcher.Add(event.Name); err != nil {
+						klog.Errorf("[local] failed to watch new sub-directory %s: %v\n", event.Name, err)
+					}
+				}
+			}
+			if !dw.matches(event.Name) {
+				continue
+			}
+			if dw.debounce <= 0 {
+				reload()
+				continue
+			}
+			if debounceTimer == nil {
+				debounceTimer = time.AfterFunc(dw.debounce, reload)
+			} else {
+				debounceTimer.Reset(dw.debounce)
+			}
+		case err, ok := <-dw.watcher.Errors:
+			if !ok {
+				return
+			}
+			klog.Errorf("directory watcher meet error: %v\n", err)
+			dw.notifyError(err)
+		case <-dw.done:
+			if debounceTimer != nil {
+				debounceTimer.Stop()
+			}
+			return
+		}
+	}
+}
+
+// CallOnceAll calls the callback function list once.
+func (dw *directoryWatcher) CallOnceAll() error {
+	data, err := dw.readAll()
+	if err != nil {
+		dw.notifyError(err)
+		return err
+	}
+
+	dw.mu.Lock()
+	callbacks := make([]func(data []byte), 0, len(dw.callbacks))
+	for _, v := range dw.callbacks {
+		callbacks = append(callbacks, v)
+	}
+	dw.mu.Unlock()
+
+	for _, v := range callbacks {
+		v(data)
+	}
+	return nil
+}
+
+// CallOnceSpecific calls the callback function once by key.
+func (dw *directoryWatcher) CallOnceSpecific(key string) error {
+	data, err := dw.readAll()
+	if err != nil {
+		dw.notifyError(err)
 		return err
 	}
 
-	if callback, ok := fw.callbacks[key]; ok {
-		callback(data)
-	} else {
+	dw.mu.Lock()
+	callback, ok := dw.callbacks[key]
+	dw.mu.Unlock()
+
+	if !ok {
 		return errors.New("not found callback for key: " + key)
 	}
+	callback(data)
 	return nil
 }
+
+// readAll re-reads every matching file under dir in stable lexicographic
+// order and deep-merges them via mergeFragments (last-writer-wins per key,
+// arrays replaced not appended) into a single document.
+func (dw *directoryWatcher) readAll() ([]byte, error) {
+	var paths []string
+	err := filepath.WalkDir(dw.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dw.dir && !dw.recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if dw.matches(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	datas := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		datas = append(datas, data)
+	}
+	return mergeFragments(datas)
+}
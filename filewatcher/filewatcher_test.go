@@ -0,0 +1,217 @@
+// Copyright 2023 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filewatcher
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it returns true or timeout elapses, failing the
+// test otherwise. fsnotify delivery is asynchronous, so the tests below
+// can't just assert immediately after triggering an fs change.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("condition not met within %s", timeout)
+}
+
+// TestFileWatcher_RenameOver covers the save-by-rename pattern used by vim
+// and most editors: write the new content to a temp file, then rename it
+// over the watched path.
+func TestFileWatcher_RenameOver(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(path, []byte("v1"), 0o644); err != nil {
+		t.Fatalf("seed file: %v", err)
+	}
+
+	fw, err := NewFileWatcher(path, WithDebounce(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewFileWatcher: %v", err)
+	}
+	defer fw.StopWatching()
+
+	var mu sync.Mutex
+	var got string
+	if err := fw.RegisterCallback(func(data []byte) {
+		mu.Lock()
+		got = string(data)
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterCallback: %v", err)
+	}
+
+	if err := fw.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+
+	tmp := filepath.Join(dir, "app.yaml.tmp")
+	if err := os.WriteFile(tmp, []byte("v2"), 0o644); err != nil {
+		t.Fatalf("write replacement: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("rename-over: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return got == "v2"
+	})
+}
+
+// TestFileWatcher_RemoveThenRecreate covers tools that delete the file and
+// recreate it moments later instead of renaming over it; the watcher must
+// report the gap via the error callback and still pick up the new content.
+func TestFileWatcher_RemoveThenRecreate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(path, []byte("v1"), 0o644); err != nil {
+		t.Fatalf("seed file: %v", err)
+	}
+
+	fw, err := NewFileWatcher(path, WithRetryInterval(20*time.Millisecond), WithRetryMaxInterval(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewFileWatcher: %v", err)
+	}
+	defer fw.StopWatching()
+
+	var mu sync.Mutex
+	var got string
+	var sawError bool
+	if err := fw.RegisterCallback(func(data []byte) {
+		mu.Lock()
+		got = string(data)
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterCallback: %v", err)
+	}
+	if err := fw.RegisterErrorCallback(func(error) {
+		mu.Lock()
+		sawError = true
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterErrorCallback: %v", err)
+	}
+
+	if err := fw.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	waitFor(t, 2*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return sawError
+	})
+
+	// give the removal a moment to settle before recreating, mirroring the
+	// real gap between a delete and a config-management tool writing the
+	// replacement.
+	time.Sleep(30 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("v2"), 0o644); err != nil {
+		t.Fatalf("recreate: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return got == "v2"
+	})
+}
+
+// TestFileWatcher_ConfigMapSymlinkSwap covers the indirection kubelet's
+// projected-volume atomic_writer uses for mounted ConfigMaps: the watched
+// file is a symlink into "..data", and an update atomically re-points
+// "..data" at a new revision directory rather than touching the watched
+// file's own directory entry.
+func TestFileWatcher_ConfigMapSymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+
+	rev1 := filepath.Join(dir, "..2024_01_01")
+	if err := os.Mkdir(rev1, 0o755); err != nil {
+		t.Fatalf("mkdir rev1: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(rev1, "app.yaml"), []byte("v1"), 0o644); err != nil {
+		t.Fatalf("seed rev1 file: %v", err)
+	}
+
+	dataLink := filepath.Join(dir, "..data")
+	if err := os.Symlink(rev1, dataLink); err != nil {
+		t.Fatalf("symlink ..data: %v", err)
+	}
+
+	appLink := filepath.Join(dir, "app.yaml")
+	if err := os.Symlink(filepath.Join("..data", "app.yaml"), appLink); err != nil {
+		t.Fatalf("symlink app.yaml: %v", err)
+	}
+
+	fw, err := NewFileWatcher(appLink, WithDebounce(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewFileWatcher: %v", err)
+	}
+	defer fw.StopWatching()
+
+	var mu sync.Mutex
+	var got string
+	if err := fw.RegisterCallback(func(data []byte) {
+		mu.Lock()
+		got = string(data)
+		mu.Unlock()
+	}, "test"); err != nil {
+		t.Fatalf("RegisterCallback: %v", err)
+	}
+
+	if err := fw.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+
+	rev2 := filepath.Join(dir, "..2024_01_02")
+	if err := os.Mkdir(rev2, 0o755); err != nil {
+		t.Fatalf("mkdir rev2: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(rev2, "app.yaml"), []byte("v2"), 0o644); err != nil {
+		t.Fatalf("seed rev2 file: %v", err)
+	}
+
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(rev2, tmpLink); err != nil {
+		t.Fatalf("symlink ..data_tmp: %v", err)
+	}
+	// kubelet's atomic_writer swaps the ..data symlink via rename, exactly
+	// like this; app.yaml's own directory entry is never touched.
+	if err := os.Rename(tmpLink, dataLink); err != nil {
+		t.Fatalf("atomic swap of ..data: %v", err)
+	}
+
+	waitFor(t, 3*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return got == "v2"
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filewatcher
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeFragments parses each byte slice, in the order given, as a YAML
+// document (JSON is a YAML subset, so JSON fragments parse the same way)
+// and deep-merges them into one document: last fragment wins per key, and
+// a key whose value is a list or scalar is replaced wholesale rather than
+// appended/combined. The result is re-marshaled to YAML so it can be handed
+// to parser.Decode exactly like a single config file.
+func mergeFragments(datas [][]byte) ([]byte, error) {
+	merged := map[string]interface{}{}
+
+	for _, data := range datas {
+		if strings.TrimSpace(string(data)) == "" {
+			continue
+		}
+
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		mergeInto(merged, doc)
+	}
+
+	return yaml.Marshal(merged)
+}
+
+// mergeInto deep-merges src into dst in place: keys present in both whose
+// values are maps are merged recursively, everything else (scalars,
+// lists) is replaced wholesale by src's value.
+func mergeInto(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if sv, ok := v.(map[string]interface{}); ok {
+			if dv, ok := dst[k].(map[string]interface{}); ok {
+				mergeInto(dv, sv)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filewatcher
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestMergeFragments_LastWriterWins proves two fragments touching different
+// keys both land in the merged document, and that for a key present in
+// both, the later fragment wins.
+func TestMergeFragments_LastWriterWins(t *testing.T) {
+	retryConfig := []byte(`
+retry:
+  MethodA:
+    failure_policy:
+      stop_policy:
+        max_retry_times: 1
+`)
+	limiterConfig := []byte(`
+limiter:
+  qps: 100
+retry:
+  MethodA:
+    failure_policy:
+      stop_policy:
+        max_retry_times: 3
+  MethodB:
+    failure_policy:
+      stop_policy:
+        max_retry_times: 2
+`)
+
+	merged, err := mergeFragments([][]byte{retryConfig, limiterConfig})
+	if err != nil {
+		t.Fatalf("mergeFragments returned error: %v", err)
+	}
+
+	var out map[string]interface{}
+	if err := yaml.Unmarshal(merged, &out); err != nil {
+		t.Fatalf("merged output is not valid YAML: %v", err)
+	}
+
+	if _, ok := out["limiter"]; !ok {
+		t.Fatalf("expected limiter key introduced by the second fragment to be present, got %#v", out)
+	}
+
+	retry, ok := out["retry"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected retry key, got %#v", out["retry"])
+	}
+	if _, ok := retry["MethodB"]; !ok {
+		t.Fatalf("expected MethodB introduced by the second fragment to be present")
+	}
+
+	methodA, ok := retry["MethodA"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected MethodA map, got %#v", retry["MethodA"])
+	}
+	failurePolicy, ok := methodA["failure_policy"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected failure_policy map, got %#v", methodA["failure_policy"])
+	}
+	stopPolicy, ok := failurePolicy["stop_policy"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected stop_policy map, got %#v", failurePolicy["stop_policy"])
+	}
+	if stopPolicy["max_retry_times"] != 3 {
+		t.Fatalf("expected the second fragment's max_retry_times=3 to win over the first's 1, got %v",
+			stopPolicy["max_retry_times"])
+	}
+}
+
+// TestMergeFragments_ArrayReplacedNotAppended proves a later fragment's list
+// for a key replaces an earlier fragment's list instead of appending to it.
+func TestMergeFragments_ArrayReplacedNotAppended(t *testing.T) {
+	first := []byte("hosts:\n  - a\n  - b\n")
+	second := []byte("hosts:\n  - c\n")
+
+	merged, err := mergeFragments([][]byte{first, second})
+	if err != nil {
+		t.Fatalf("mergeFragments returned error: %v", err)
+	}
+
+	var out map[string]interface{}
+	if err := yaml.Unmarshal(merged, &out); err != nil {
+		t.Fatalf("merged output is not valid YAML: %v", err)
+	}
+
+	hosts, ok := out["hosts"].([]interface{})
+	if !ok {
+		t.Fatalf("expected hosts list, got %#v", out["hosts"])
+	}
+	if len(hosts) != 1 || hosts[0] != "c" {
+		t.Fatalf("expected the second fragment's single-element list to replace the first's, got %#v", hosts)
+	}
+}
+
+// TestMergeFragments_JSONFragment proves a JSON fragment (a valid YAML
+// subset) merges correctly alongside a YAML one, instead of the combined
+// bytes being invalid input for a single-document decoder.
+func TestMergeFragments_JSONFragment(t *testing.T) {
+	yamlFragment := []byte("limiter:\n  qps: 100\n")
+	jsonFragment := []byte(`{"retry": {"MethodA": {"failure_policy": {"stop_policy": {"max_retry_times": 2}}}}}`)
+
+	merged, err := mergeFragments([][]byte{yamlFragment, jsonFragment})
+	if err != nil {
+		t.Fatalf("mergeFragments returned error: %v", err)
+	}
+
+	var out map[string]interface{}
+	if err := yaml.Unmarshal(merged, &out); err != nil {
+		t.Fatalf("merged output is not valid YAML: %v", err)
+	}
+	if _, ok := out["limiter"]; !ok {
+		t.Fatalf("expected limiter key from the YAML fragment, got %#v", out)
+	}
+	if _, ok := out["retry"]; !ok {
+		t.Fatalf("expected retry key from the JSON fragment, got %#v", out)
+	}
+}
@@ -16,40 +16,49 @@ package monitor
 
 import (
 	"errors"
-	"os"
 	"sync"
 
 	"github.com/cloudwego/kitex/pkg/klog"
-	"github.com/kitex-contrib/config-file/filewatcher"
 	"github.com/kitex-contrib/config-file/parser"
 )
 
+// Source abstracts the origin of config bytes that a ConfigMonitor watches.
+// filewatcher.FileWatcher and configmapwatcher.ConfigMapWatcher both satisfy
+// this interface, so a ConfigMonitor can sit on top of either without
+// depending on a concrete watcher implementation.
+type Source interface {
+	RegisterCallback(callback func(data []byte), key string) error
+	DeregisterCallback(key string)
+	RegisterErrorCallback(callback func(err error), key string) error
+	DeregisterErrorCallback(key string)
+	StartWatching() error
+	StopWatching()
+	CallOnceAll() error
+	CallOnceSpecific(key string) error
+}
+
 type ConfigMonitor struct {
-	manager     parser.ConfigManager     // Manager for the config file
-	config      interface{}              // config details
-	fileWatcher *filewatcher.FileWatcher // local config file watcher
-	callbacks   map[string]func()        // callbacks when config file changed
-	key         string                   // key
-	mu          sync.Mutex               // mutex
+	manager        parser.ConfigManager // Manager for the config file
+	config         interface{}          // config details
+	source         Source               // config source, e.g. a file watcher or a ConfigMap watcher
+	callbacks      map[string]func()    // callbacks when config file changed
+	errorCallbacks map[string]func(err error)
+	key            string     // key
+	mu             sync.Mutex // mutex
 }
 
-// NewConfigMonitor init a monitor for the config file
-func NewConfigMonitor(key string, watcher *filewatcher.FileWatcher) (*ConfigMonitor, error) {
-	var err error
+// NewConfigMonitor init a monitor for the config source
+func NewConfigMonitor(key string, source Source) (*ConfigMonitor, error) {
 	if key == "" {
 		return nil, errors.New("empty config key")
 	}
-	if watcher == nil {
-		return nil, errors.New("filewatcher is nil")
-	}
-
-	if err != nil {
-		return nil, err
+	if source == nil {
+		return nil, errors.New("source is nil")
 	}
 
 	return &ConfigMonitor{
-		fileWatcher: watcher,
-		key:         key,
+		source: source,
+		key:    key,
 	}, nil
 }
 
@@ -59,29 +68,33 @@ func (c *ConfigMonitor) Key() string { return c.key }
 // Config return the config details
 func (c *ConfigMonitor) Config() interface{} { return c.config }
 
-// Start starts the file watch progress
+// Start starts the config source watch progress
 func (c *ConfigMonitor) Start() error {
 	if c.manager == nil {
 		return errors.New("not set manager for config file")
 	}
 
-	data, err := os.ReadFile(c.fileWatcher.FilePath())
-	if err != nil {
-		klog.Errorf("[local] read config file failed: %v\n", err)
+	if err := c.source.RegisterCallback(c.parseHandler, c.key); err != nil { // use key as callback key
+		return err
+	}
+	if err := c.source.RegisterErrorCallback(c.notifyError, c.key); err != nil { // use key as callback key
 		return err
 	}
-	c.parseHandler(data)
-	return c.fileWatcher.RegisterCallback(c.parseHandler, c.key) // use key as callback key
+	return c.source.CallOnceSpecific(c.key)
 }
 
-// Stop stops the file watch progress
+// Stop stops the config source watch progress
 func (c *ConfigMonitor) Stop() {
 	for k := range c.callbacks {
 		c.DeregisterCallback(k)
 	}
+	for k := range c.errorCallbacks {
+		c.DeregisterErrorCallback(k)
+	}
 
-	// deregister current object's parseHandler from filewatcher
-	c.fileWatcher.DeregisterCallback(c.key)
+	// deregister current object's handlers from the source
+	c.source.DeregisterCallback(c.key)
+	c.source.DeregisterErrorCallback(c.key)
 }
 
 // SetManager set the manager for the config file
@@ -109,12 +122,50 @@ func (c *ConfigMonitor) DeregisterCallback(key string) {
 	delete(c.callbacks, key)
 }
 
+// RegisterErrorCallback add a callback function invoked when the config
+// source fails to watch, read or parse the config, e.g. for metrics/alerts.
+func (c *ConfigMonitor) RegisterErrorCallback(callback func(err error), key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.errorCallbacks == nil {
+		c.errorCallbacks = make(map[string]func(err error))
+	}
+	c.errorCallbacks[key] = callback
+}
+
+// DeregisterErrorCallback remove error callback function.
+func (c *ConfigMonitor) DeregisterErrorCallback(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.errorCallbacks[key]; !exists {
+		klog.Warnf("[local] ConfigMonitor error callback %s not registered", key)
+		return
+	}
+	delete(c.errorCallbacks, key)
+}
+
+// notifyError invokes every registered error callback.
+func (c *ConfigMonitor) notifyError(err error) {
+	c.mu.Lock()
+	callbacks := make([]func(error), 0, len(c.errorCallbacks))
+	for _, callback := range c.errorCallbacks {
+		callbacks = append(callbacks, callback)
+	}
+	c.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(err)
+	}
+}
+
 // parseHandler parse and invoke each function in the callbacks array
 func (c *ConfigMonitor) parseHandler(data []byte) {
 	resp := c.manager
 	err := parser.Decode(data, resp)
 	if err != nil {
 		klog.Errorf("[local] failed to parse the config file: %v\n", err)
+		c.notifyError(err)
 		return
 	}
 